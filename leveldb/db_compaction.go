@@ -7,6 +7,8 @@
 package leveldb
 
 import (
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -62,10 +64,101 @@ func (p *cStatsStaging) stopTimer() {
 	}
 }
 
+// compactionIORate reports the current read/write token bucket depth, as
+// surfaced through DB.GetProperty("leveldb.compaction-io-rate").
+func (db *DB) compactionIORate() string {
+	var read, write int64 = -1, -1
+	if limiter := db.s.o.GetReadRateLimiter(); limiter != nil {
+		read = limiter.Depth()
+	}
+	if limiter := db.s.o.GetWriteRateLimiter(); limiter != nil {
+		write = limiter.Depth()
+	}
+	return fmt.Sprintf("read-bucket:%d write-bucket:%d", read, write)
+}
+
+// compactionProperty handles the leveldb.* property names owned by the
+// compaction subsystem. DB.GetProperty's main switch (db.go) should
+// delegate to this for any name it doesn't otherwise recognize, rather
+// than this living as a second top-level GetProperty — a DB can only
+// have one.
+//
+// Property names:
+//
+//	leveldb.compaction-io-rate
+//		Returns the current compaction read/write rate-limiter bucket
+//		depth, see compactionIORate.
+func (db *DB) compactionProperty(name string) (value string, ok bool) {
+	const prefix = "leveldb."
+	if !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+	switch name[len(prefix):] {
+	case "compaction-io-rate":
+		return db.compactionIORate(), true
+	default:
+		return "", false
+	}
+}
+
+// eventListenerTimeout bounds how long a slow opt.EventListener callback
+// may block the caller before fireEvent gives up on it.
+const eventListenerTimeout = 50 * time.Millisecond
+
+// fireEvent runs fn, an opt.EventListener callback, without letting it
+// stall the compaction goroutine: a panic inside fn is swallowed and a
+// callback that doesn't return within eventListenerTimeout is abandoned
+// (its goroutine is left to finish on its own).
+func (db *DB) fireEvent(fn func()) {
+	if !runWithTimeout(fn, eventListenerTimeout) {
+		db.logf("event listener callback timed out")
+	}
+}
+
+// runWithTimeout runs fn in its own goroutine, swallowing any panic, and
+// reports whether it completed within timeout; this is fireEvent's core
+// and is split out so it's testable without a *DB to log through.
+func runWithTimeout(fn func(), timeout time.Duration) (completed bool) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() { recover() }()
+		fn()
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// LevelStats is a per-level snapshot of compaction statistics, as
+// returned by DB.CompactionStats.
+type LevelStats struct {
+	Level    int
+	Duration time.Duration
+	Read     uint64
+	Write    uint64
+}
+
+// CompactionStats returns a snapshot of per-level compaction read/write
+// volume and time spent, for integrators that want structured metrics
+// instead of parsing db.logf strings.
+func (db *DB) CompactionStats() []LevelStats {
+	stats := make([]LevelStats, len(db.compStats))
+	for level := range db.compStats {
+		duration, read, write := db.compStats[level].get()
+		stats[level] = LevelStats{Level: level, Duration: duration, Read: read, Write: write}
+	}
+	return stats
+}
+
 type cMem struct {
-	s     *session
-	level int
-	rec   *sessionRecord
+	s          *session
+	level      int
+	rec        *sessionRecord
+	imin, imax []byte // user-key bounds of the flushed table, for OnFlushEnd
 }
 
 func newCMem(s *session) *cMem {
@@ -94,6 +187,7 @@ func (c *cMem) flush(mem *memdb.DB, level int) error {
 	s.logf("mem@flush created L%d@%d N·%d S·%s %q:%q", level, t.file.Num(), n, shortenb(int(t.size)), t.imin, t.imax)
 
 	c.level = level
+	c.imin, c.imax = t.imin.ukey(), t.imax.ukey()
 	return nil
 }
 
@@ -114,6 +208,7 @@ func (db *DB) compactionError() {
 		err     error
 		wlocked bool
 	)
+	listener := db.s.o.GetEventListener()
 noerr:
 	// No error.
 	for {
@@ -131,6 +226,9 @@ noerr:
 		}
 	}
 haserr:
+	if listener != nil {
+		db.fireEvent(func() { listener.OnCompactionError(&opt.CompactionErrorEvent{Err: err}) })
+	}
 	// Transient error.
 	for {
 		select {
@@ -148,6 +246,9 @@ haserr:
 		}
 	}
 hasperr:
+	if listener != nil {
+		db.fireEvent(func() { listener.OnCompactionError(&opt.CompactionErrorEvent{Err: err}) })
+	}
 	// Persistent error.
 	for {
 		select {
@@ -156,6 +257,9 @@ hasperr:
 		case db.writeLockC <- struct{}{}:
 			// Hold write lock, so that write won't pass-through.
 			wlocked = true
+			if listener != nil {
+				db.fireEvent(func() { listener.OnStall(&opt.StallEvent{Reason: "persistent compaction error"}) })
+			}
 		case _, _ = <-db.closeC:
 			if wlocked {
 				// We should release the lock or Close will hang.
@@ -172,7 +276,14 @@ func (cnt *compactionTransactCounter) incr() {
 	*cnt++
 }
 
-func (db *DB) compactionTransact(name string, exec func(cnt *compactionTransactCounter) error, rollback func() error) {
+// compactionTransact runs exec, retrying with backoff on error until it
+// succeeds, and returns the number of retries (0 if the first attempt
+// succeeded). It gives up by panicking with errCompactionTransactExiting
+// when the DB is closed, a persistent error is reported, or exec returns
+// a corruption error. rollback, if non-nil, is invoked whenever
+// errCompactionTransactExiting unwinds through this call, whether exec or
+// compactionTransact itself raised it.
+func (db *DB) compactionTransact(name string, exec func(cnt *compactionTransactCounter) error, rollback func() error) int {
 	defer func() {
 		if x := recover(); x != nil {
 			if x == errCompactionTransactExiting && rollback != nil {
@@ -225,7 +336,7 @@ func (db *DB) compactionTransact(name string, exec func(cnt *compactionTransactC
 			db.compactionExitTransact()
 		}
 		if err == nil {
-			return
+			return n
 		}
 		if errors.IsCorrupted(err) {
 			db.logf("%s exiting (corruption detected)", name)
@@ -270,6 +381,7 @@ func (db *DB) memCompaction() {
 
 	c := newCMem(db.s)
 	stats := new(cStatsStaging)
+	listener := db.s.o.GetEventListener()
 
 	db.logf("mem@flush N·%d S·%s", mem.mdb.Len(), shortenb(mem.mdb.Size()))
 
@@ -281,6 +393,12 @@ func (db *DB) memCompaction() {
 		return
 	}
 
+	if listener != nil {
+		db.fireEvent(func() {
+			listener.OnFlushBegin(&opt.FlushEvent{NumEntries: mem.mdb.Len(), Size: int64(mem.mdb.Size())})
+		})
+	}
+
 	// Pause table compaction.
 	resumeC := make(chan struct{})
 	select {
@@ -295,6 +413,11 @@ func (db *DB) memCompaction() {
 	db.compactionTransact("mem@flush", func(cnt *compactionTransactCounter) (err error) {
 		stats.startTimer()
 		defer stats.stopTimer()
+		if limiter := db.s.o.GetWriteRateLimiter(); limiter != nil {
+			if !limiter.Request(mem.mdb.Size(), db.closeC) {
+				db.compactionExitTransact()
+			}
+		}
 		return c.flush(mem.mdb, -1)
 	}, func() error {
 		for _, r := range c.rec.addedTables {
@@ -320,6 +443,14 @@ func (db *DB) memCompaction() {
 	}
 	db.compStats[c.level].add(stats)
 
+	if listener != nil {
+		ev := &opt.FlushEvent{Level: c.level, Size: int64(stats.write), Duration: stats.duration, Imin: c.imin, Imax: c.imax}
+		if len(c.rec.addedTables) > 0 {
+			ev.FileNum = int64(c.rec.addedTables[0].num)
+		}
+		db.fireEvent(func() { listener.OnFlushEnd(ev) })
+	}
+
 	// Drop frozen mem.
 	db.dropFrozenMem()
 
@@ -354,18 +485,339 @@ func (db *DB) tableCompaction(c *compaction, noTrivial bool) {
 		return
 	}
 
+	var (
+		readLimiter  = db.s.o.GetReadRateLimiter()
+		writeLimiter = db.s.o.GetWriteRateLimiter()
+	)
+
 	var stats [2]cStatsStaging
 	for i, tables := range c.tables {
 		for _, t := range tables {
 			stats[i].read += t.size
 			// Insert deleted tables into record
 			rec.delTable(c.level+i, t.file.Num())
+			// Throttle compaction reads so they don't starve foreground
+			// writes; a closed db.closeC preempts the wait.
+			if readLimiter != nil {
+				if !readLimiter.Request(int(t.size), db.closeC) {
+					db.compactionExitTransact()
+				}
+			}
 		}
 	}
 	sourceSize := int(stats[0].read + stats[1].read)
 	minSeq := db.minSeq()
 	db.logf("table@compaction L%d·%d -> L%d·%d S·%s Q·%d", c.level, len(c.tables[0]), c.level+1, len(c.tables[1]), shortenb(sourceSize), minSeq)
 
+	listener := db.s.o.GetEventListener()
+	if listener != nil {
+		ev := &opt.CompactionBeginEvent{Level: c.level, OutputLevel: c.level + 1, InputSize: int64(sourceSize)}
+		for i, tables := range c.tables {
+			for _, t := range tables {
+				ev.InputFileNums[i] = append(ev.InputFileNums[i], int64(t.file.Num()))
+			}
+		}
+		db.fireEvent(func() { listener.OnCompactionBegin(ev) })
+	}
+
+	var (
+		strict    = db.s.o.GetStrict(opt.StrictCompaction)
+		tableSize = db.s.o.GetCompactionTableSize(c.level + 1)
+
+		kerrCnt int
+		dropCnt int
+		retries int
+
+		outMin, outMax []byte
+	)
+
+	// Split the input range into (up to) MaxSubcompactions contiguous
+	// subranges and build each one concurrently. With the default of 1
+	// this degenerates to a single worker covering the whole range, i.e.
+	// today's behavior.
+	nsub := db.s.o.GetMaxSubcompactions()
+	if nsub < 1 {
+		nsub = 1
+	}
+	ranges := splitCompactionRange(c, nsub)
+
+	// Only one goroutine may ever receive from db.tcompPauseC, so a
+	// dedicated relay fans a single pause request out to every worker via
+	// gate, pausing them all for as long as the requester holds it.
+	gate := newPauseGate()
+	relayDone := make(chan struct{})
+	go db.compactionPauseRelay(gate, relayDone)
+
+	subs := make([]*subcompaction, len(ranges))
+	var wg sync.WaitGroup
+	for i, rg := range ranges {
+		wg.Add(1)
+		go func(i int, lo, hi []byte) {
+			defer wg.Done()
+			subs[i] = db.runSubcompaction(c, i, lo, hi, minSeq, tableSize, strict, readLimiter, writeLimiter, gate)
+		}(i, rg[0], rg[1])
+	}
+	wg.Wait()
+	close(relayDone)
+
+	// A worker that exits via compactionExitTransact has already rolled
+	// back its own tables (compactionTransact's rollback path); roll back
+	// every sibling that did succeed and re-raise so the failure is
+	// handled exactly like a single-subcompaction failure was before.
+	var failed interface{}
+	for _, sub := range subs {
+		if sub.panicVal != nil {
+			failed = sub.panicVal
+		}
+	}
+	if failed != nil {
+		for _, sub := range subs {
+			for _, t := range sub.tables {
+				db.logf("table@build rollback @%d", t.file.Num())
+				if err := db.s.getTableFile(t.file.Num()).Remove(); err != nil {
+					db.logf("table@build rollback @%d error %q", t.file.Num(), err)
+				}
+			}
+		}
+		// errCompactionTransactExiting is the ordinary shutdown/backoff
+		// sentinel (db.isClosed(), a persistent error already reported
+		// via compactionError, or corruption already logged by
+		// compactionTransact) — not a compaction error in its own right,
+		// so don't report it to the listener.
+		if listener != nil && failed != errCompactionTransactExiting {
+			if err, ok := failed.(error); ok {
+				var failedRetries int
+				for _, sub := range subs {
+					failedRetries += sub.retries
+				}
+				db.fireEvent(func() {
+					listener.OnCompactionError(&opt.CompactionErrorEvent{Level: c.level, Err: err, Retries: failedRetries})
+				})
+			}
+		}
+		panic(failed)
+	}
+
+	for _, sub := range subs {
+		for _, t := range sub.tables {
+			rec.addTableFile(c.level+1, t)
+			stats[1].write += t.size
+
+			tmin, tmax := t.imin.ukey(), t.imax.ukey()
+			if outMin == nil || db.s.icmp.uCompare(tmin, outMin) < 0 {
+				outMin = tmin
+			}
+			if outMax == nil || db.s.icmp.uCompare(tmax, outMax) > 0 {
+				outMax = tmax
+			}
+		}
+		stats[1].duration += sub.stats.duration
+		kerrCnt += sub.kerrCnt
+		dropCnt += sub.dropCnt
+		retries += sub.retries
+	}
+
+	// Commit changes
+	db.compactionTransact("table@commit", func(cnt *compactionTransactCounter) (err error) {
+		stats[1].startTimer()
+		defer stats[1].stopTimer()
+		return db.s.commit(rec)
+	}, nil)
+
+	resultSize := int(stats[1].write)
+	db.logf("table@compaction committed F%s S%s Ke·%d D·%d T·%v", sint(len(rec.addedTables)-len(rec.deletedTables)), sshortenb(resultSize-sourceSize), kerrCnt, dropCnt, stats[1].duration)
+
+	// Save compaction stats
+	for i := range stats {
+		db.compStats[c.level+1].add(&stats[i])
+	}
+
+	if listener != nil {
+		ev := &opt.CompactionEndEvent{
+			Level:       c.level,
+			OutputLevel: c.level + 1,
+			InputSize:   int64(sourceSize),
+			OutputSize:  int64(resultSize),
+			Imin:        outMin,
+			Imax:        outMax,
+			Duration:    stats[1].duration,
+			KerrCnt:     kerrCnt,
+			DropCnt:     dropCnt,
+			Retries:     retries,
+		}
+		for _, t := range rec.addedTables {
+			ev.OutputFileNums = append(ev.OutputFileNums, int64(t.num))
+		}
+		db.fireEvent(func() { listener.OnCompactionEnd(ev) })
+	}
+}
+
+// splitCompactionRange partitions a tableCompaction's input range into up
+// to n contiguous, non-overlapping [lo, hi) subranges (nil bounds meaning
+// unbounded) for parallel subcompactions. Cut points are level+1 input
+// files' boundary keys: the first one at which the running byte total
+// reaches each multiple of total/n, so each worker gets roughly
+// sourceSize/n to build. It always returns at least
+// one range; with too few level+1 files to find n-1 cuts it returns
+// fewer than n.
+//
+// The actual splitting math lives in splitSizedRange, which only needs
+// each file's size and upper-bound user key, not a *compaction — that
+// keeps it unit-testable on its own.
+func splitCompactionRange(c *compaction, n int) [][2][]byte {
+	files := c.tables[1]
+	sizes := make([]int64, len(files))
+	maxUkeys := make([][]byte, len(files))
+	for i, t := range files {
+		sizes[i] = t.size
+		maxUkeys[i] = t.imax.ukey()
+	}
+	return splitSizedRange(sizes, maxUkeys, n)
+}
+
+// splitSizedRange is the comparator- and type-free core of
+// splitCompactionRange: given the byte size and upper-bound user key of
+// each input file in order, it picks up to n-1 cut points, each the upper
+// key of the first file at which the running total reaches the next
+// multiple of total/n (the last file is never a cut), and returns the
+// resulting [lo, hi) subranges.
+func splitSizedRange(sizes []int64, maxUkeys [][]byte, n int) [][2][]byte {
+	if n <= 1 || len(sizes) < n {
+		return [][2][]byte{{nil, nil}}
+	}
+
+	var total int64
+	for _, size := range sizes {
+		total += size
+	}
+	target := total / int64(n)
+
+	var bounds [][]byte
+	var acc int64
+	for i, size := range sizes[:len(sizes)-1] {
+		acc += size
+		if acc >= target*int64(len(bounds)+1) {
+			bounds = append(bounds, append([]byte{}, maxUkeys[i]...))
+			if len(bounds) == n-1 {
+				break
+			}
+		}
+	}
+	if len(bounds) == 0 {
+		return [][2][]byte{{nil, nil}}
+	}
+
+	ranges := make([][2][]byte, 0, len(bounds)+1)
+	lo := []byte(nil)
+	for _, hi := range bounds {
+		ranges = append(ranges, [2][]byte{lo, hi})
+		lo = hi
+	}
+	ranges = append(ranges, [2][]byte{lo, nil})
+	return ranges
+}
+
+// pauseGate lets every subcompaction worker of one tableCompaction honor
+// a single pause request together. db.tcompPauseC only ever hands its
+// payload to one receiver, so compactionPauseRelay is the sole reader and
+// flips the gate for all workers at once.
+type pauseGate struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	paused bool
+}
+
+func newPauseGate() *pauseGate {
+	g := &pauseGate{}
+	g.cond = sync.NewCond(&g.mu)
+	return g
+}
+
+func (g *pauseGate) wait() {
+	g.mu.Lock()
+	for g.paused {
+		g.cond.Wait()
+	}
+	g.mu.Unlock()
+}
+
+func (g *pauseGate) setPaused(paused bool) {
+	g.mu.Lock()
+	g.paused = paused
+	g.cond.Broadcast()
+	g.mu.Unlock()
+}
+
+// compactionPauseRelay receives pause requests on db.tcompPauseC on
+// behalf of all subcompaction workers spawned by one tableCompaction, and
+// blocks (via the same handshake pauseCompaction always used) for as long
+// as the requester holds the pause, with every worker parked on gate for
+// the duration.
+//
+// db.pauseCompaction panics with errCompactionTransactExiting if
+// db.closeC fires while it's waiting for the ack to be read; unlike every
+// other caller of it, this goroutine has no compactionTransact or
+// tCompaction frame above it to recover that panic, so it must catch it
+// itself to avoid crashing the process on a close racing a pause. That
+// panic unwinds straight past a plain gate.setPaused(false) statement
+// placed after the call, which would leave gate stuck paused forever (and
+// every subcompaction worker parked in gate.wait() hung with it) with no
+// one left to unpause it — so the unpause runs via defer instead,
+// guaranteeing it happens whether pauseCompaction returns normally or
+// panics.
+func (db *DB) compactionPauseRelay(gate *pauseGate, done <-chan struct{}) {
+	defer func() {
+		if x := recover(); x != nil && x != errCompactionTransactExiting {
+			panic(x)
+		}
+	}()
+	for {
+		select {
+		case ch := <-db.tcompPauseC:
+			gate.setPaused(true)
+			func() {
+				defer gate.setPaused(false)
+				db.pauseCompaction(ch)
+			}()
+		case <-done:
+			return
+		case _, _ = <-db.closeC:
+			return
+		}
+	}
+}
+
+// subcompaction is the independent state of one worker inside a
+// parallelized tableCompaction: its own key range, retry snapshot and
+// output tables, so compactionTransact can retry it without disturbing
+// its siblings.
+type subcompaction struct {
+	idx    int
+	lo, hi []byte // restricts the merged iterator to [lo, hi)
+
+	tables []*tFile
+	stats  cStatsStaging
+
+	kerrCnt, dropCnt int
+	retries          int // number of table@build retries, see compactionTransact
+
+	panicVal interface{} // recovered panic (usually compactionExitTransact's), if any
+}
+
+// runSubcompaction runs the merge-iterate-emit loop for one subrange of a
+// tableCompaction and reports its outcome via the returned *subcompaction.
+// It recovers any panic, compactionExitTransact's included, rather than
+// letting it cross the goroutine boundary; the caller re-raises it after rolling back
+// every subcompaction's output, once all of them have returned.
+func (db *DB) runSubcompaction(c *compaction, idx int, lo, hi []byte, minSeq uint64, tableSize int, strict bool, readLimiter, writeLimiter opt.RateLimiter, gate *pauseGate) *subcompaction {
+	sub := &subcompaction{idx: idx, lo: lo, hi: hi}
+
+	defer func() {
+		if x := recover(); x != nil {
+			sub.panicVal = x
+		}
+	}()
+
 	var (
 		snapHasLastUkey bool
 		snapLastUkey    []byte
@@ -373,19 +825,14 @@ func (db *DB) tableCompaction(c *compaction, noTrivial bool) {
 		snapIter        int
 		snapKerrCnt     int
 		snapDropCnt     int
-
-		kerrCnt int
-		dropCnt int
-
-		strict    = db.s.o.GetStrict(opt.StrictCompaction)
-		tableSize = db.s.o.GetCompactionTableSize(c.level + 1)
 	)
-	db.compactionTransact("table@build", func(cnt *compactionTransactCounter) (err error) {
+
+	sub.retries = db.compactionTransact(fmt.Sprintf("table@build#%d", idx), func(cnt *compactionTransactCounter) (err error) {
 		hasLastUkey := snapHasLastUkey // The key might has zero length, so this is necessary.
 		lastUkey := append([]byte{}, snapLastUkey...)
 		lastSeq := snapLastSeq
-		kerrCnt = snapKerrCnt
-		dropCnt = snapDropCnt
+		sub.kerrCnt = snapKerrCnt
+		sub.dropCnt = snapDropCnt
 		snapSched := snapIter == 0
 
 		var tw *tWriter
@@ -394,23 +841,31 @@ func (db *DB) tableCompaction(c *compaction, noTrivial bool) {
 			if err != nil {
 				return err
 			}
-			rec.addTableFile(c.level+1, t)
-			stats[1].write += t.size
-			db.logf("table@build created L%d@%d N·%d S·%s %q:%q", c.level+1, t.file.Num(), tw.tw.EntriesLen(), shortenb(int(t.size)), t.imin, t.imax)
+			if writeLimiter != nil {
+				if !writeLimiter.Request(int(t.size), db.closeC) {
+					db.compactionExitTransact()
+				}
+			}
+			sub.tables = append(sub.tables, t)
+			sub.stats.write += t.size
+			db.logf("table@build#%d created L%d@%d N·%d S·%s %q:%q", idx, c.level+1, t.file.Num(), tw.tw.EntriesLen(), shortenb(int(t.size)), t.imin, t.imax)
 			return nil
 		}
 
 		defer func() {
-			stats[1].stopTimer()
+			sub.stats.stopTimer()
 			if tw != nil {
 				tw.drop()
 				tw = nil
 			}
 		}()
 
-		stats[1].startTimer()
+		sub.stats.startTimer()
 		iter := c.newIterator()
 		defer iter.Release()
+		if lo != nil {
+			iter.Seek(newIkey(lo, kMaxSeq, ktSeek))
+		}
 		for i := 0; iter.Next(); i++ {
 			// Incr transact counter.
 			cnt.incr()
@@ -423,6 +878,40 @@ func (db *DB) tableCompaction(c *compaction, noTrivial bool) {
 			ikey := iter.Key()
 			ukey, seq, kt, kerr := parseIkey(ikey)
 
+			// Stop at this worker's upper bound; the next subrange's
+			// worker owns ukey onward. A corrupted key (kerr != nil) is
+			// still emitted below when not strict, so the bound must be
+			// checked against the key's user-key prefix directly rather
+			// than the (nil-on-error) ukey parseIkey returns, or this
+			// worker would run past hi into territory the next worker is
+			// independently seeking to, duplicating keys across both
+			// outputs.
+			//
+			// A key too short to even hold the trailing seq/type
+			// (len(ikey) < 8) has no usable prefix to compare, so
+			// there's no way to tell it apart from one that belongs to
+			// the next subrange. Emitting it here isn't safe either: the
+			// next worker's iterator seeks to hi independently, and with
+			// no reliable ordering for this key against that seek bound
+			// it could land on the same entry and emit it too, which is
+			// the exact duplication this check exists to prevent. So it
+			// is dropped, but counted so the loss is visible instead of
+			// silent.
+			if hi != nil {
+				if len(ikey) < 8 {
+					sub.dropCnt++
+					db.logf("table@build#%d dropping corrupted too-short key at subrange boundary", idx)
+					continue
+				}
+				boundUkey := ukey
+				if kerr != nil {
+					boundUkey = ikey[:len(ikey)-8]
+				}
+				if db.s.icmp.uCompare(boundUkey, hi) >= 0 {
+					break
+				}
+			}
+
 			// Skip this if key is corrupted.
 			if kerr == nil && c.shouldStopBefore(ikey) && tw != nil {
 				err = finish()
@@ -440,8 +929,8 @@ func (db *DB) tableCompaction(c *compaction, noTrivial bool) {
 				snapLastUkey = append(snapLastUkey[:0], lastUkey...)
 				snapLastSeq = lastSeq
 				snapIter = i
-				snapKerrCnt = kerrCnt
-				snapDropCnt = dropCnt
+				snapKerrCnt = sub.kerrCnt
+				snapDropCnt = sub.dropCnt
 				snapSched = false
 			}
 
@@ -466,7 +955,7 @@ func (db *DB) tableCompaction(c *compaction, noTrivial bool) {
 					//     few iterations of this loop (by rule (A) above).
 					// Therefore this deletion marker is obsolete and can be dropped.
 					lastSeq = seq
-					dropCnt++
+					sub.dropCnt++
 					continue
 				default:
 					lastSeq = seq
@@ -480,15 +969,15 @@ func (db *DB) tableCompaction(c *compaction, noTrivial bool) {
 				hasLastUkey = false
 				lastUkey = lastUkey[:0]
 				lastSeq = kMaxSeq
-				kerrCnt++
+				sub.kerrCnt++
 			}
 
 			// Create new table if not already
 			if tw == nil {
-				// Check for pause event.
+				// Pausing halts every worker together; gate.wait only
+				// blocks while a pause request is in effect.
+				gate.wait()
 				select {
-				case ch := <-db.tcompPauseC:
-					db.pauseCompaction(ch)
 				case _, _ = <-db.closeC:
 					db.compactionExitTransact()
 				default:
@@ -533,30 +1022,17 @@ func (db *DB) tableCompaction(c *compaction, noTrivial bool) {
 		}
 		return
 	}, func() error {
-		for _, r := range rec.addedTables {
-			db.logf("table@build rollback @%d", r.num)
-			f := db.s.getTableFile(r.num)
-			if err := f.Remove(); err != nil {
+		for _, t := range sub.tables {
+			db.logf("table@build#%d rollback @%d", idx, t.file.Num())
+			if err := db.s.getTableFile(t.file.Num()).Remove(); err != nil {
 				return err
 			}
 		}
+		sub.tables = nil
 		return nil
 	})
 
-	// Commit changes
-	db.compactionTransact("table@commit", func(cnt *compactionTransactCounter) (err error) {
-		stats[1].startTimer()
-		defer stats[1].stopTimer()
-		return db.s.commit(rec)
-	}, nil)
-
-	resultSize := int(stats[1].write)
-	db.logf("table@compaction committed F%s S%s Ke·%d D·%d T·%v", sint(len(rec.addedTables)-len(rec.deletedTables)), sshortenb(resultSize-sourceSize), kerrCnt, dropCnt, stats[1].duration)
-
-	// Save compaction stats
-	for i := range stats {
-		db.compStats[c.level+1].add(&stats[i])
-	}
+	return sub
 }
 
 func (db *DB) tableRangeCompaction(level int, umin, umax []byte) {
@@ -0,0 +1,162 @@
+// Copyright (c) 2012, Suryandaru Triandana <syndtr@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package leveldb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitSizedRange_TooFewFiles(t *testing.T) {
+	sizes := []int64{10, 10}
+	maxUkeys := [][]byte{[]byte("b"), []byte("d")}
+	got := splitSizedRange(sizes, maxUkeys, 4)
+	want := [][2][]byte{{nil, nil}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestSplitSizedRange_EvenSplit(t *testing.T) {
+	sizes := []int64{10, 10, 10, 10}
+	maxUkeys := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	got := splitSizedRange(sizes, maxUkeys, 2)
+	want := [][2][]byte{
+		{nil, []byte("b")},
+		{[]byte("b"), nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestSplitSizedRange_UnevenSizesPickNearestCut(t *testing.T) {
+	// Total is 100; a 2-way split targets 50 per side. The running sum
+	// only reaches >=50 at the 3rd file (10+10+40=60), so the cut should
+	// land on its boundary key rather than an earlier or later one.
+	sizes := []int64{10, 10, 40, 40}
+	maxUkeys := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+	got := splitSizedRange(sizes, maxUkeys, 2)
+	want := [][2][]byte{
+		{nil, []byte("c")},
+		{[]byte("c"), nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestSplitSizedRange_NoCutsFound(t *testing.T) {
+	// A single huge last file leaves nothing in files[:len-1] whose
+	// running sum ever reaches target, so no bound is ever emitted and
+	// the whole range must come back unsplit.
+	sizes := []int64{1, 1000}
+	maxUkeys := [][]byte{[]byte("a"), []byte("b")}
+	got := splitSizedRange(sizes, maxUkeys, 2)
+	want := [][2][]byte{{nil, nil}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestSplitSizedRange_DisableParallelism(t *testing.T) {
+	sizes := []int64{10, 10}
+	maxUkeys := [][]byte{[]byte("a"), []byte("b")}
+	got := splitSizedRange(sizes, maxUkeys, 1)
+	want := [][2][]byte{{nil, nil}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestPauseGate_WaitBlocksUntilUnpaused(t *testing.T) {
+	g := newPauseGate()
+	g.setPaused(true)
+
+	woke := make(chan struct{})
+	go func() {
+		g.wait()
+		close(woke)
+	}()
+
+	select {
+	case <-woke:
+		t.Fatal("wait returned while still paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	g.setPaused(false)
+
+	select {
+	case <-woke:
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after setPaused(false)")
+	}
+}
+
+func TestPauseGate_WaitNoopWhenNotPaused(t *testing.T) {
+	g := newPauseGate()
+
+	done := make(chan struct{})
+	go func() {
+		g.wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("wait blocked although the gate was never paused")
+	}
+}
+
+func TestPauseGate_BroadcastWakesAllWaiters(t *testing.T) {
+	g := newPauseGate()
+	g.setPaused(true)
+
+	const n = 8
+	woke := make(chan struct{}, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			g.wait()
+			woke <- struct{}{}
+		}()
+	}
+
+	// Give every goroutine a chance to reach cond.Wait() before unpausing.
+	time.Sleep(20 * time.Millisecond)
+	g.setPaused(false)
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-woke:
+		case <-time.After(time.Second):
+			t.Fatalf("only %d/%d waiters woke up", i, n)
+		}
+	}
+}
+
+func TestRunWithTimeout_CompletesInTime(t *testing.T) {
+	if !runWithTimeout(func() {}, time.Second) {
+		t.Fatal("expected a fast callback to report completed")
+	}
+}
+
+func TestRunWithTimeout_TimesOut(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	if runWithTimeout(func() { <-release }, 10*time.Millisecond) {
+		t.Fatal("expected a slow callback to report timed out")
+	}
+}
+
+func TestRunWithTimeout_SwallowsPanic(t *testing.T) {
+	if !runWithTimeout(func() { panic("boom") }, time.Second) {
+		t.Fatal("expected a panicking callback to still report completed")
+	}
+}
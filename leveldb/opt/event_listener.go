@@ -0,0 +1,72 @@
+// Copyright (c) 2012, Suryandaru Triandana <syndtr@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package opt
+
+import "time"
+
+// FlushEvent describes a single memdb flush (an immutable memtable being
+// written out as a new table, at the level reported in Level).
+type FlushEvent struct {
+	Level      int
+	FileNum    int64
+	NumEntries int
+	Size       int64
+	Imin, Imax []byte
+	Duration   time.Duration
+}
+
+// CompactionBeginEvent describes the input side of a table compaction
+// about to start.
+type CompactionBeginEvent struct {
+	Level         int
+	OutputLevel   int
+	InputFileNums [2][]int64
+	InputSize     int64
+}
+
+// CompactionEndEvent describes the outcome of a table compaction.
+type CompactionEndEvent struct {
+	Level          int
+	OutputLevel    int
+	OutputFileNums []int64
+	InputSize      int64
+	OutputSize     int64
+	Imin, Imax     []byte
+	Duration       time.Duration
+	KerrCnt        int
+	DropCnt        int
+	Retries        int
+}
+
+// CompactionErrorEvent describes a compaction (or flush) that failed.
+type CompactionErrorEvent struct {
+	Level   int
+	Err     error
+	Retries int
+}
+
+// StallEvent describes foreground writes being blocked on behalf of
+// compaction. It is delivered when a persistent compaction error holds
+// the write lock; write slowdowns caused by too many level-0 files do not
+// produce a StallEvent.
+type StallEvent struct {
+	Reason   string
+	Duration time.Duration
+}
+
+// EventListener receives structured compaction/flush events. Callbacks
+// must not block for long: the caller protects against a slow listener
+// with a timeout, but a listener that never returns still leaks a
+// goroutine per call.
+type EventListener interface {
+	OnFlushBegin(*FlushEvent)
+	OnFlushEnd(*FlushEvent)
+	OnCompactionBegin(*CompactionBeginEvent)
+	OnCompactionEnd(*CompactionEndEvent)
+	OnCompactionError(*CompactionErrorEvent)
+	OnStall(*StallEvent)
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2012, Suryandaru Triandana <syndtr@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package opt
+
+import "testing"
+
+type nopEventListener struct{}
+
+func (nopEventListener) OnFlushBegin(*FlushEvent)                {}
+func (nopEventListener) OnFlushEnd(*FlushEvent)                  {}
+func (nopEventListener) OnCompactionBegin(*CompactionBeginEvent) {}
+func (nopEventListener) OnCompactionEnd(*CompactionEndEvent)     {}
+func (nopEventListener) OnCompactionError(*CompactionErrorEvent) {}
+func (nopEventListener) OnStall(*StallEvent)                     {}
+
+func TestOptions_EventListener(t *testing.T) {
+	var o *Options
+	if o.GetEventListener() != nil {
+		t.Fatal("expected nil Options to report a nil EventListener")
+	}
+
+	o = &Options{}
+	if o.GetEventListener() != nil {
+		t.Fatal("expected zero-value Options to report a nil EventListener")
+	}
+
+	l := nopEventListener{}
+	o = &Options{EventListener: l}
+	if o.GetEventListener() != EventListener(l) {
+		t.Fatal("expected configured EventListener to be returned as-is")
+	}
+}
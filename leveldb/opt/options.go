@@ -0,0 +1,153 @@
+// Copyright (c) 2012, Suryandaru Triandana <syndtr@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package opt
+
+// Strict is a bitset of behaviors that can be made stricter than their
+// lenient defaults.
+type Strict uint
+
+const (
+	// StrictManifest forces db.recoverJournal to fail on newer unknown
+	// manifest record types.
+	StrictManifest Strict = 1 << iota
+	// StrictJournalChecksum forces a corrupted journal to be treated as
+	// an unrecoverable error.
+	StrictJournalChecksum
+	// StrictJournal forces db.recoverJournal to fail on newer unknown
+	// journal record types.
+	StrictJournal
+	// StrictHandleCorruption forces the database to fail instead of
+	// ignoring corruption of data or meta keys.
+	StrictHandleCorruption
+	// StrictReader forces a reader to fail on checksum mismatch.
+	StrictReader
+	// StrictCompaction forces compaction to fail, instead of ignoring
+	// and dropping, on corrupted input keys.
+	StrictCompaction
+
+	// StrictAll enables all strict flags.
+	StrictAll = StrictManifest | StrictJournalChecksum | StrictJournal | StrictHandleCorruption | StrictReader | StrictCompaction
+
+	// DefaultStrict is the default strict flags used if not explicitly
+	// set via Options.Strict.
+	DefaultStrict = StrictJournalChecksum | StrictHandleCorruption
+)
+
+const (
+	// DefaultNumLevel is the default number of levels used if
+	// Options.NumLevel is not set.
+	DefaultNumLevel = 7
+	// DefaultCompactionTableSize is the default target size, in bytes,
+	// of a table produced by compaction, used if
+	// Options.CompactionTableSize is not set.
+	DefaultCompactionTableSize = 2 * 1048576
+)
+
+// Options holds the tunables consulted by a DB's compaction path.
+type Options struct {
+	// NumLevel is the number of level-based LSM levels. The default
+	// value is DefaultNumLevel.
+	NumLevel int
+
+	// Strict sets the DB strict level. The default value is
+	// DefaultStrict.
+	Strict Strict
+
+	// CompactionTableSize limits the size, in bytes, of a table produced
+	// by compaction. The default value is DefaultCompactionTableSize.
+	CompactionTableSize int
+
+	// DisableCompactionBackoff, if true, disables the exponential
+	// backoff that compactionTransact otherwise applies between retries
+	// of a failed compaction.
+	DisableCompactionBackoff bool
+
+	// ReadRateLimiter, if non-nil, throttles the bytes read by
+	// compaction from input tables. It may be shared across multiple DB
+	// instances. A nil ReadRateLimiter (the default) means unlimited.
+	ReadRateLimiter RateLimiter
+
+	// WriteRateLimiter, if non-nil, throttles the bytes written by
+	// compaction (memdb flushes and table builds). It may be shared
+	// across multiple DB instances, and may be the same RateLimiter as
+	// ReadRateLimiter. A nil WriteRateLimiter (the default) means
+	// unlimited.
+	WriteRateLimiter RateLimiter
+
+	// MaxSubcompactions caps how many subcompactions a single table
+	// compaction may split its input range into and build concurrently.
+	// The default of 1 reproduces the non-parallel behavior.
+	MaxSubcompactions int
+
+	// EventListener, if non-nil, receives structured flush/compaction
+	// events. The default of nil delivers no events.
+	EventListener EventListener
+}
+
+func (o *Options) GetNumLevel() int {
+	if o == nil || o.NumLevel <= 0 {
+		return DefaultNumLevel
+	}
+	return o.NumLevel
+}
+
+func (o *Options) GetStrict(strict Strict) bool {
+	if o == nil || o.Strict == 0 {
+		return DefaultStrict&strict != 0
+	}
+	return o.Strict&strict != 0
+}
+
+func (o *Options) GetCompactionTableSize(level int) int {
+	if o == nil || o.CompactionTableSize <= 0 {
+		return DefaultCompactionTableSize
+	}
+	return o.CompactionTableSize
+}
+
+func (o *Options) GetDisableCompactionBackoff() bool {
+	if o == nil {
+		return false
+	}
+	return o.DisableCompactionBackoff
+}
+
+// GetReadRateLimiter returns the configured read RateLimiter, or nil
+// (unlimited) if none was set.
+func (o *Options) GetReadRateLimiter() RateLimiter {
+	if o == nil {
+		return nil
+	}
+	return o.ReadRateLimiter
+}
+
+// GetWriteRateLimiter returns the configured write RateLimiter, or nil
+// (unlimited) if none was set.
+func (o *Options) GetWriteRateLimiter() RateLimiter {
+	if o == nil {
+		return nil
+	}
+	return o.WriteRateLimiter
+}
+
+// GetMaxSubcompactions returns the configured subcompaction fan-out,
+// defaulting to 1 (no parallelism) if unset.
+func (o *Options) GetMaxSubcompactions() int {
+	if o == nil || o.MaxSubcompactions < 1 {
+		return 1
+	}
+	return o.MaxSubcompactions
+}
+
+// GetEventListener returns the configured EventListener, or nil if none
+// was set.
+func (o *Options) GetEventListener() EventListener {
+	if o == nil {
+		return nil
+	}
+	return o.EventListener
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2012, Suryandaru Triandana <syndtr@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package opt
+
+import "testing"
+
+func TestOptions_MaxSubcompactions(t *testing.T) {
+	var o *Options
+	if n := o.GetMaxSubcompactions(); n != 1 {
+		t.Fatalf("expected nil Options to default to 1, got %d", n)
+	}
+
+	o = &Options{}
+	if n := o.GetMaxSubcompactions(); n != 1 {
+		t.Fatalf("expected zero-value Options to default to 1, got %d", n)
+	}
+
+	o = &Options{MaxSubcompactions: 4}
+	if n := o.GetMaxSubcompactions(); n != 4 {
+		t.Fatalf("expected configured MaxSubcompactions to be returned, got %d", n)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2012, Suryandaru Triandana <syndtr@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package opt
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles the I/O performed by background compactions so
+// that they don't starve foreground writes on slow storage. A RateLimiter
+// may be shared between multiple DB instances, in which case all of them
+// compete for the same rate.
+//
+// Request blocks until n bytes worth of tokens are available, or until
+// cancel is closed. It reports whether the request was granted; a false
+// return means cancel fired before enough tokens became available and no
+// tokens were consumed.
+type RateLimiter interface {
+	Request(n int, cancel <-chan struct{}) bool
+
+	// SetRate adjusts the refill rate, in bytes/sec, of the limiter. It
+	// may be called concurrently with Request.
+	SetRate(bytesPerSec float64)
+
+	// Depth returns the number of tokens currently available in the
+	// bucket, mainly for diagnostics (e.g. DB.GetProperty).
+	Depth() int64
+}
+
+// TokenBucket is the default RateLimiter implementation. It refills
+// lazily, on each call, based on elapsed wall-clock time, so it costs
+// nothing while idle.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // bytes/sec
+	burst  int64
+	tokens int64
+	last   time.Time
+}
+
+// NewTokenBucket creates a token-bucket RateLimiter that allows
+// bytesPerSec bytes per second on average, with bursts up to burst
+// bytes. A bytesPerSec of 0 means unthrottled: every Request is granted
+// immediately regardless of burst.
+func NewTokenBucket(bytesPerSec float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:   bytesPerSec,
+		burst:  int64(burst),
+		tokens: int64(burst),
+		last:   time.Now(),
+	}
+}
+
+// refill tops tokens up based on elapsed time, capped at burst. tokens may
+// be negative (a request larger than burst is granted by going into debt,
+// see Request), in which case refill only narrows the debt.
+func (b *TokenBucket) refill() {
+	now := time.Now()
+	if b.rate > 0 {
+		elapsed := now.Sub(b.last)
+		b.tokens += int64(elapsed.Seconds() * b.rate)
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// Request implements RateLimiter.
+//
+// A request for more than burst tokens is still honored, not rejected:
+// tokens are capped at burst only to bound how much idle time can buy in
+// advance, not how much a single request may draw down. Request instead
+// goes into debt for the shortfall and waits the time needed for that
+// debt, at rate, to have been earned.
+func (b *TokenBucket) Request(n int, cancel <-chan struct{}) bool {
+	b.mu.Lock()
+	b.refill()
+	var wait time.Duration
+	if need := int64(n) - b.tokens; need > 0 && b.rate > 0 {
+		wait = time.Duration(float64(need) / b.rate * float64(time.Second))
+	}
+	b.tokens -= int64(n)
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return true
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-cancel:
+		// Not granted; give back the debt we provisionally took on.
+		// Refill first so the refund is clamped to burst same as any
+		// other accumulation, instead of letting a canceled request
+		// push tokens above the configured cap.
+		b.mu.Lock()
+		b.refill()
+		b.tokens += int64(n)
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.mu.Unlock()
+		return false
+	}
+}
+
+// SetRate implements RateLimiter.
+func (b *TokenBucket) SetRate(bytesPerSec float64) {
+	b.mu.Lock()
+	b.refill()
+	b.rate = bytesPerSec
+	b.mu.Unlock()
+}
+
+// Depth implements RateLimiter.
+func (b *TokenBucket) Depth() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	return b.tokens
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2012, Suryandaru Triandana <syndtr@gmail.com>
+// All rights reserved.
+//
+// Use of this source code is governed by a BSD-style license that can be
+// found in the LICENSE file.
+
+package opt
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_BurstIsImmediatelyAvailable(t *testing.T) {
+	b := NewTokenBucket(1024, 4096)
+	if !b.Request(4096, nil) {
+		t.Fatal("expected full burst to be granted immediately")
+	}
+	if depth := b.Depth(); depth != 0 {
+		t.Fatalf("expected bucket to be drained, got depth %d", depth)
+	}
+}
+
+func TestTokenBucket_RefillsOverTime(t *testing.T) {
+	b := NewTokenBucket(1024*1024, 1024) // 1MB/s, tiny burst
+	if !b.Request(1024, nil) {
+		t.Fatal("expected initial burst to be granted")
+	}
+	if !b.Request(512, nil) {
+		t.Fatal("expected request to be satisfied after refill")
+	}
+}
+
+func TestTokenBucket_CancelUnblocksWait(t *testing.T) {
+	b := NewTokenBucket(1, 1) // effectively no meaningful refill within the test window
+	if !b.Request(1, nil) {
+		t.Fatal("expected burst token to be granted")
+	}
+
+	cancel := make(chan struct{})
+	done := make(chan bool, 1)
+	go func() {
+		done <- b.Request(1<<20, cancel)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	close(cancel)
+
+	select {
+	case granted := <-done:
+		if granted {
+			t.Fatal("expected Request to report cancellation, not a grant")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Request did not honor cancel")
+	}
+}
+
+func TestTokenBucket_SetRate(t *testing.T) {
+	b := NewTokenBucket(0, 8)
+	if !b.Request(8, nil) {
+		t.Fatal("expected burst to be granted")
+	}
+	b.SetRate(1 << 30) // effectively unthrottled
+	if !b.Request(1<<20, nil) {
+		t.Fatal("expected request to be granted after raising the rate")
+	}
+}
+
+func TestOptions_RateLimiters(t *testing.T) {
+	var o *Options
+	if o.GetReadRateLimiter() != nil || o.GetWriteRateLimiter() != nil {
+		t.Fatal("expected nil Options to report unlimited (nil) rate limiters")
+	}
+
+	rl := NewTokenBucket(1, 1)
+	o = &Options{ReadRateLimiter: rl, WriteRateLimiter: rl}
+	if o.GetReadRateLimiter() != RateLimiter(rl) || o.GetWriteRateLimiter() != RateLimiter(rl) {
+		t.Fatal("expected configured rate limiters to be returned as-is")
+	}
+}